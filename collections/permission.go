@@ -0,0 +1,26 @@
+package collections
+
+import (
+	"github.com/imKota/permify_gorm/models"
+)
+
+// Permission keeps permission collection.
+type Permission []models.Permission
+
+// IDs get id's of permissions.
+func (p Permission) IDs() (IDs []uint) {
+	for _, permission := range p {
+		IDs = append(IDs, permission.ID)
+	}
+	return
+}
+
+// Len get length of permissions.
+func (p Permission) Len() int64 {
+	return int64(len(p))
+}
+
+// Origin converts the collection back to its underlying model slice.
+func (p Permission) Origin() []models.Permission {
+	return p
+}
@@ -0,0 +1,21 @@
+package collections
+
+import (
+	"github.com/imKota/permify_gorm/models"
+)
+
+// Role keeps role collection.
+type Role []models.Role
+
+// IDs get id's of roles.
+func (r Role) IDs() (IDs []uint) {
+	for _, role := range r {
+		IDs = append(IDs, role.ID)
+	}
+	return
+}
+
+// Len get length of roles.
+func (r Role) Len() int64 {
+	return int64(len(r))
+}
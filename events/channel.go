@@ -0,0 +1,17 @@
+package events
+
+// ChannelPublisher publishes events onto a buffered channel. It is primarily
+// meant for tests that need to assert which events a repository call emitted.
+type ChannelPublisher struct {
+	Events chan Event
+}
+
+// NewChannelPublisher creates a ChannelPublisher with the given channel buffer size.
+func NewChannelPublisher(buffer int) *ChannelPublisher {
+	return &ChannelPublisher{Events: make(chan Event, buffer)}
+}
+
+// Publish implements EventPublisher.
+func (publisher *ChannelPublisher) Publish(event Event) {
+	publisher.Events <- event
+}
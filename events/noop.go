@@ -0,0 +1,7 @@
+package events
+
+// NoopPublisher discards every event it receives.
+type NoopPublisher struct{}
+
+// Publish implements EventPublisher.
+func (NoopPublisher) Publish(Event) {}
@@ -0,0 +1,34 @@
+package events
+
+// EventType names a role/permission lifecycle event.
+type EventType string
+
+const (
+	RoleCreated         EventType = "role.created"
+	RoleDeleted         EventType = "role.deleted"
+	PermissionsAttached EventType = "role.permissions_attached"
+	PermissionsDetached EventType = "role.permissions_detached"
+	UserRoleAssigned    EventType = "role.user_role_assigned"
+)
+
+// Event describes a single role or permission change.
+type Event struct {
+	Type   EventType
+	RoleID uint
+
+	// UserID and Scope are set on UserRoleAssigned events.
+	UserID uint
+	Scope  string
+
+	// Before and After carry the permission id sets involved in an attach or
+	// detach, so a subscriber can diff grants without re-querying the role.
+	Before []uint
+	After  []uint
+}
+
+// EventPublisher is implemented by anything that wants to observe role and
+// permission changes, e.g. to invalidate a permission cache, write an audit
+// log entry, or push a live update to connected clients.
+type EventPublisher interface {
+	Publish(event Event)
+}
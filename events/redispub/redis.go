@@ -0,0 +1,38 @@
+// Package redispub ships a Redis-backed events.EventPublisher. It is kept out
+// of the core events package so that consumers who use NoopPublisher or
+// ChannelPublisher (or no publisher at all) aren't forced to pull in a Redis
+// client dependency.
+package redispub
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/imKota/permify_gorm/events"
+)
+
+// Publisher publishes events to a Redis pub/sub channel so other processes
+// (permission caches, audit writers, WebSocket gateways) can react to role
+// and permission changes in real time.
+type Publisher struct {
+	Client  *redis.Client
+	Channel string
+}
+
+// NewPublisher creates a Publisher that publishes to the given channel.
+func NewPublisher(client *redis.Client, channel string) *Publisher {
+	return &Publisher{Client: client, Channel: channel}
+}
+
+// Publish implements events.EventPublisher. Marshalling and publish errors
+// are swallowed since EventPublisher.Publish has no error return; a broken
+// event sink must not break the repository call that triggered it.
+func (publisher *Publisher) Publish(event events.Event) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	publisher.Client.Publish(context.Background(), publisher.Channel, payload)
+}
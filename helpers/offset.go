@@ -0,0 +1,6 @@
+package helpers
+
+// OffsetCal calculates the row offset for the given page and limit.
+func OffsetCal(page, limit int) int {
+	return (page - 1) * limit
+}
@@ -0,0 +1,44 @@
+package matching
+
+import (
+	"strings"
+	"sync"
+)
+
+// Cache caches split wildcard patterns so repeated permission checks don't
+// re-split the same guard name pattern on every call.
+type Cache struct {
+	separator string
+
+	mu       sync.RWMutex
+	segments map[string][]string
+}
+
+// NewCache creates a pattern Cache that splits patterns and names on separator.
+// An empty separator falls back to DefaultSeparator.
+func NewCache(separator string) *Cache {
+	if separator == "" {
+		separator = DefaultSeparator
+	}
+	return &Cache{separator: separator, segments: make(map[string][]string)}
+}
+
+// Matches reports whether name matches the given (cached) pattern.
+func (cache *Cache) Matches(pattern, name string) bool {
+	return matchSegments(cache.split(pattern), strings.Split(name, cache.separator))
+}
+
+func (cache *Cache) split(pattern string) []string {
+	cache.mu.RLock()
+	segments, ok := cache.segments[pattern]
+	cache.mu.RUnlock()
+	if ok {
+		return segments
+	}
+
+	segments = strings.Split(pattern, cache.separator)
+	cache.mu.Lock()
+	cache.segments[pattern] = segments
+	cache.mu.Unlock()
+	return segments
+}
@@ -0,0 +1,46 @@
+package matching
+
+import (
+	"strings"
+)
+
+// DefaultSeparator splits a guard name into segments for wildcard matching,
+// e.g. "posts:delete" into ["posts", "delete"].
+const DefaultSeparator = ":"
+
+// Match reports whether the concrete guard name matches the wildcard
+// pattern's segments. A "*" segment matches exactly one segment; a "**"
+// segment matches any number of remaining segments, including zero.
+func Match(pattern, name, separator string) bool {
+	if separator == "" {
+		separator = DefaultSeparator
+	}
+	return matchSegments(strings.Split(pattern, separator), strings.Split(name, separator))
+}
+
+func matchSegments(pattern, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+
+	switch pattern[0] {
+	case "**":
+		if matchSegments(pattern[1:], name) {
+			return true
+		}
+		if len(name) == 0 {
+			return false
+		}
+		return matchSegments(pattern, name[1:])
+	case "*":
+		if len(name) == 0 {
+			return false
+		}
+		return matchSegments(pattern[1:], name[1:])
+	default:
+		if len(name) == 0 || name[0] != pattern[0] {
+			return false
+		}
+		return matchSegments(pattern[1:], name[1:])
+	}
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+)
+
+// Permission keeps permission model.
+type Permission struct {
+	ID        uint   `gorm:"primarykey"`
+	Name      string `gorm:"size:191;not null;"`
+	GuardName string `gorm:"size:191;not null;unique"`
+
+	// IsPattern marks GuardName as a wildcard pattern (e.g. "posts:*",
+	// "admin.**") to be resolved against a requested permission at check
+	// time, instead of matched exactly.
+	IsPattern bool `gorm:"not null;default:false;index"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,17 @@
+package pivot
+
+// UserRoles keeps user & role pivot.
+type UserRoles struct {
+	UserID uint `gorm:"primarykey;auto_increment:false"`
+	RoleID uint `gorm:"primarykey;auto_increment:false"`
+
+	// Scope ties the grant to a tenant/organization/project so the same user
+	// can hold different roles in different scopes. An empty Scope is a
+	// global grant, which keeps existing rows backwards compatible.
+	Scope string `gorm:"primarykey;size:191;not null;default:'';index"`
+}
+
+// TableName overrides default table name.
+func (UserRoles) TableName() string {
+	return "user_roles"
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+)
+
+// Role keeps role model.
+type Role struct {
+	ID        uint   `gorm:"primarykey"`
+	Name      string `gorm:"size:191;not null;"`
+	GuardName string `gorm:"size:191;not null;unique"`
+
+	// ParentRoleID points at the role this role inherits permissions from.
+	// A nil ParentRoleID means the role is a root of its hierarchy.
+	ParentRoleID *uint `gorm:"index"`
+	ParentRole   *Role `gorm:"foreignKey:ParentRoleID"`
+
+	Permissions []Permission `gorm:"many2many:role_permissions;"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
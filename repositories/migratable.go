@@ -0,0 +1,6 @@
+package repositories
+
+// Migratable is implemented by repositories that can create their own tables.
+type Migratable interface {
+	Migrate() (err error)
+}
@@ -1,14 +1,37 @@
 package repositories
 
 import (
+	"context"
+	"errors"
+	"sync"
+
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/imKota/permify_gorm/collections"
+	"github.com/imKota/permify_gorm/events"
+	"github.com/imKota/permify_gorm/matching"
 	"github.com/imKota/permify_gorm/models"
 	"github.com/imKota/permify_gorm/models/pivot"
 	"github.com/imKota/permify_gorm/repositories/scopes"
 )
 
+// ErrCycleDetected is returned by SetParent when assigning the given parent
+// would create a cycle in the role hierarchy.
+var ErrCycleDetected = errors.New("permify_gorm: assigning this parent would introduce a cycle in the role hierarchy")
+
+// roleAncestorsCTE walks the role hierarchy upwards from the given role ids,
+// used by drivers that support recursive common table expressions.
+const roleAncestorsCTE = `
+WITH RECURSIVE role_ancestors AS (
+	SELECT id, parent_role_id FROM roles WHERE id IN (?)
+	UNION ALL
+	SELECT roles.id, roles.parent_role_id FROM roles
+	INNER JOIN role_ancestors ON roles.id = role_ancestors.parent_role_id
+)
+SELECT roles.* FROM roles INNER JOIN role_ancestors ON roles.id = role_ancestors.id
+`
+
 // IRoleRepository its data access layer abstraction of role.
 type IRoleRepository interface {
 	Migratable
@@ -17,6 +40,7 @@ type IRoleRepository interface {
 
 	GetRoleByID(ID uint) (role models.Role, err error)
 	GetRoleByIDWithPermissions(ID uint) (role models.Role, err error)
+	GetRoleByIDWithInheritedPermissions(ID uint) (role models.Role, err error)
 
 	GetRoleByGuardName(guardName string) (role models.Role, err error)
 	GetRoleByGuardNameWithPermissions(guardName string) (role models.Role, err error)
@@ -32,7 +56,7 @@ type IRoleRepository interface {
 	// ID fetch options
 
 	GetRoleIDs(pagination scopes.GormPager) (roleIDs []uint, totalCount int64, err error)
-	GetRoleIDsOfUser(userID uint, pagination scopes.GormPager) (roleIDs []uint, totalCount int64, err error)
+	GetRoleIDsOfUser(userID uint, pagination scopes.GormPager, scope ...string) (roleIDs []uint, totalCount int64, err error)
 	GetRoleIDsOfPermission(permissionID uint, pagination scopes.GormPager) (roleIDs []uint, totalCount int64, err error)
 
 	// FirstOrCreate & Updates & Delete
@@ -41,6 +65,18 @@ type IRoleRepository interface {
 	Updates(role *models.Role, updates map[string]interface{}) (err error)
 	Delete(role *models.Role) (err error)
 
+	// Hierarchy
+
+	SetParent(role *models.Role, parent *models.Role) (err error)
+	GetAncestors(role models.Role) (ancestors collections.Role, err error)
+	GetDescendants(role models.Role) (descendants collections.Role, err error)
+
+	// Scoped assignments
+
+	AddRoleToUserInScope(userID uint, roleID uint, scope string) (err error)
+	RemoveRoleFromUserInScope(userID uint, roleID uint, scope string) (err error)
+	GetRolesOfUserInScope(userID uint, scope string) (roles collections.Role, err error)
+
 	// Actions
 
 	AddPermissions(role *models.Role, permissions collections.Permission) (err error)
@@ -48,16 +84,142 @@ type IRoleRepository interface {
 	RemovePermissions(role *models.Role, permissions collections.Permission) (err error)
 	ClearPermissions(role *models.Role) (err error)
 
+	SyncPermissions(role *models.Role, desired collections.Permission) (added collections.Permission, removed collections.Permission, err error)
+	SyncRolesOfUser(userID uint, desiredRoleIDs []uint, scope ...string) (added []uint, removed []uint, err error)
+
 	// Controls
 
 	HasPermission(roles collections.Role, permission models.Permission) (b bool, err error)
 	HasAllPermissions(roles collections.Role, permissions collections.Permission) (b bool, err error)
 	HasAnyPermissions(roles collections.Role, permissions collections.Permission) (b bool, err error)
+
+	HasPermissionInScope(userID uint, scope string, permission models.Permission) (b bool, err error)
+	HasAllPermissionsInScope(userID uint, scope string, permissions collections.Permission) (b bool, err error)
+	HasAnyPermissionsInScope(userID uint, scope string, permissions collections.Permission) (b bool, err error)
+
+	// Context-aware variants. Each threads ctx into the underlying query via
+	// gorm's Database.WithContext, so callers can propagate deadlines,
+	// tracing spans, or cancellation into every query this repository makes.
+
+	MigrateCtx(ctx context.Context) (err error)
+
+	GetRoleByIDCtx(ctx context.Context, ID uint) (role models.Role, err error)
+	GetRoleByIDWithPermissionsCtx(ctx context.Context, ID uint) (role models.Role, err error)
+	GetRoleByIDWithInheritedPermissionsCtx(ctx context.Context, ID uint) (role models.Role, err error)
+
+	GetRoleByGuardNameCtx(ctx context.Context, guardName string) (role models.Role, err error)
+	GetRoleByGuardNameWithPermissionsCtx(ctx context.Context, guardName string) (role models.Role, err error)
+
+	GetRolesCtx(ctx context.Context, roleIDs []uint) (roles collections.Role, err error)
+	GetRolesWithPermissionsCtx(ctx context.Context, roleIDs []uint) (roles collections.Role, err error)
+
+	GetRolesByGuardNamesCtx(ctx context.Context, guardNames []string) (roles collections.Role, err error)
+	GetRolesByGuardNamesWithPermissionsCtx(ctx context.Context, guardNames []string) (roles collections.Role, err error)
+
+	GetRoleIDsCtx(ctx context.Context, pagination scopes.GormPager) (roleIDs []uint, totalCount int64, err error)
+	GetRoleIDsOfUserCtx(ctx context.Context, userID uint, pagination scopes.GormPager, scope ...string) (roleIDs []uint, totalCount int64, err error)
+	GetRoleIDsOfPermissionCtx(ctx context.Context, permissionID uint, pagination scopes.GormPager) (roleIDs []uint, totalCount int64, err error)
+
+	FirstOrCreateCtx(ctx context.Context, role *models.Role) (err error)
+	UpdatesCtx(ctx context.Context, role *models.Role, updates map[string]interface{}) (err error)
+	DeleteCtx(ctx context.Context, role *models.Role) (err error)
+
+	SetParentCtx(ctx context.Context, role *models.Role, parent *models.Role) (err error)
+	GetAncestorsCtx(ctx context.Context, role models.Role) (ancestors collections.Role, err error)
+	GetDescendantsCtx(ctx context.Context, role models.Role) (descendants collections.Role, err error)
+
+	AddRoleToUserInScopeCtx(ctx context.Context, userID uint, roleID uint, scope string) (err error)
+	RemoveRoleFromUserInScopeCtx(ctx context.Context, userID uint, roleID uint, scope string) (err error)
+	GetRolesOfUserInScopeCtx(ctx context.Context, userID uint, scope string) (roles collections.Role, err error)
+
+	AddPermissionsCtx(ctx context.Context, role *models.Role, permissions collections.Permission) (err error)
+	ReplacePermissionsCtx(ctx context.Context, role *models.Role, permissions collections.Permission) (err error)
+	RemovePermissionsCtx(ctx context.Context, role *models.Role, permissions collections.Permission) (err error)
+	ClearPermissionsCtx(ctx context.Context, role *models.Role) (err error)
+
+	SyncPermissionsCtx(ctx context.Context, role *models.Role, desired collections.Permission) (added collections.Permission, removed collections.Permission, err error)
+	SyncRolesOfUserCtx(ctx context.Context, userID uint, desiredRoleIDs []uint, scope ...string) (added []uint, removed []uint, err error)
+
+	HasPermissionCtx(ctx context.Context, roles collections.Role, permission models.Permission) (b bool, err error)
+	HasAllPermissionsCtx(ctx context.Context, roles collections.Role, permissions collections.Permission) (b bool, err error)
+	HasAnyPermissionsCtx(ctx context.Context, roles collections.Role, permissions collections.Permission) (b bool, err error)
+
+	HasPermissionInScopeCtx(ctx context.Context, userID uint, scope string, permission models.Permission) (b bool, err error)
+	HasAllPermissionsInScopeCtx(ctx context.Context, userID uint, scope string, permissions collections.Permission) (b bool, err error)
+	HasAnyPermissionsInScopeCtx(ctx context.Context, userID uint, scope string, permissions collections.Permission) (b bool, err error)
 }
 
 // RoleRepository its data access layer of role.
 type RoleRepository struct {
 	Database *gorm.DB
+
+	// Events, when set, is notified of role and permission changes. It is
+	// left nil by default so constructing a RoleRepository with just a
+	// Database keeps working; set it to events.NoopPublisher{} or any other
+	// EventPublisher to observe changes.
+	Events events.EventPublisher
+
+	// PatternSeparator splits a guard name into segments for wildcard
+	// permission matching. Defaults to matching.DefaultSeparator when empty.
+	PatternSeparator string
+
+	// cache holds the lazily-initialized wildcard pattern cache and recursive
+	// CTE support check. It's a pointer shared across withContext's shallow
+	// clones, so state populated through a *Ctx call is visible to every
+	// other clone (and the original) of the same repository instead of being
+	// thrown away with the clone.
+	cache *roleRepoCache
+}
+
+// roleRepoCache holds RoleRepository state that must outlive any single
+// withContext clone.
+type roleRepoCache struct {
+	mu         sync.Mutex
+	patterns   *matching.Cache
+	cteSupport *bool
+}
+
+// sharedCache returns the repository's cache, creating it lazily so a
+// RoleRepository constructed with just a Database keeps working. It mutates
+// the receiver, so callers that are about to shallow-copy the repository
+// (see withContext) must call this first to make sure the clone shares the
+// same cache pointer as the original.
+// @return *roleRepoCache
+func (repository *RoleRepository) sharedCache() *roleRepoCache {
+	if repository.cache == nil {
+		repository.cache = &roleRepoCache{}
+	}
+	return repository.cache
+}
+
+// patternCache returns the repository's wildcard pattern cache, creating it
+// lazily so a RoleRepository constructed with just a Database keeps working.
+// @return *matching.Cache
+func (repository *RoleRepository) patternCache() *matching.Cache {
+	cache := repository.sharedCache()
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if cache.patterns == nil {
+		cache.patterns = matching.NewCache(repository.PatternSeparator)
+	}
+	return cache.patterns
+}
+
+// publish notifies repository.Events of an event, if one is configured.
+// @param events.Event
+func (repository *RoleRepository) publish(event events.Event) {
+	if repository.Events == nil {
+		return
+	}
+	repository.Events.Publish(event)
+}
+
+// permissionIDsOf returns the ids of the permissions currently granted to the role.
+// @param *models.Role
+// @return []uint, error
+func (repository *RoleRepository) permissionIDsOf(role *models.Role) (ids []uint, err error) {
+	err = repository.Database.Table("role_permissions").Where("role_permissions.role_id = ?", role.ID).Pluck("role_permissions.permission_id", &ids).Error
+	return
 }
 
 // Migrate generate tables from the database.
@@ -102,6 +264,40 @@ func (repository *RoleRepository) GetRoleByGuardNameWithPermissions(guardName st
 	return
 }
 
+// GetRoleByIDWithInheritedPermissions get role by id with the union of its
+// direct permissions and every permission inherited from its ancestor roles.
+// @param uint
+// @return models.Role, error
+func (repository *RoleRepository) GetRoleByIDWithInheritedPermissions(ID uint) (role models.Role, err error) {
+	if role, err = repository.GetRoleByIDWithPermissions(ID); err != nil {
+		return
+	}
+
+	var ancestors collections.Role
+	if ancestors, err = repository.GetAncestors(role); err != nil || ancestors.Len() == 0 {
+		return
+	}
+
+	var withAncestorPermissions collections.Role
+	if withAncestorPermissions, err = repository.GetRolesWithPermissions(ancestors.IDs()); err != nil {
+		return
+	}
+
+	seen := make(map[uint]bool, len(role.Permissions))
+	for _, permission := range role.Permissions {
+		seen[permission.ID] = true
+	}
+	for _, ancestor := range withAncestorPermissions {
+		for _, permission := range ancestor.Permissions {
+			if !seen[permission.ID] {
+				seen[permission.ID] = true
+				role.Permissions = append(role.Permissions, permission)
+			}
+		}
+	}
+	return
+}
+
 // MULTIPLE FETCH OPTIONS
 
 // GetRoles get roles by ids.
@@ -139,28 +335,81 @@ func (repository *RoleRepository) GetRolesByGuardNamesWithPermissions(guardNames
 // ID FETCH OPTIONS
 
 // GetRoleIDs get role ids. (with pagination)
+// When pagination is a *scopes.KeysetPager, the COUNT(*) is skipped
+// (totalCount is -1) and pagination.Next is set to the cursor to resume from.
 // @param repositories_scopes.GormPager
 // @return []uint, int64, error
 func (repository *RoleRepository) GetRoleIDs(pagination scopes.GormPager) (roleIDs []uint, totalCount int64, err error) {
-	err = repository.Database.Model(&models.Role{}).Count(&totalCount).Scopes(repository.paginate(pagination)).Pluck("roles.id", &roleIDs).Error
+	query := repository.Database.Model(&models.Role{})
+
+	totalCount = -1
+	if pagination == nil || !pagination.SkipCount() {
+		query = query.Count(&totalCount)
+	}
+
+	if err = query.Scopes(repository.paginate(pagination)).Pluck("roles.id", &roleIDs).Error; err != nil {
+		return
+	}
+
+	if keyset, ok := pagination.(*scopes.KeysetPager); ok && len(roleIDs) > 0 {
+		keyset.Next = roleIDs[len(roleIDs)-1]
+	}
 	return
 }
 
 // GetRoleIDsOfUser get role ids of user. (with pagination)
+// An optional scope restricts the result to role grants made in that scope;
+// omitting it returns grants across every scope, matching the old behavior.
+// When pagination is a *scopes.KeysetPager (use Column: "role_id"), the
+// COUNT(*) is skipped (totalCount is -1) and pagination.Next is set to the
+// cursor to resume from.
 // @param uint
 // @param repositories_scopes.GormPager
+// @param ...string
 // @return []uint, int64, error
-func (repository *RoleRepository) GetRoleIDsOfUser(userID uint, pagination scopes.GormPager) (roleIDs []uint, totalCount int64, err error) {
-	err = repository.Database.Table("user_roles").Where("user_roles.user_id = ?", userID).Count(&totalCount).Scopes(repository.paginate(pagination)).Pluck("user_roles.role_id", &roleIDs).Error
+func (repository *RoleRepository) GetRoleIDsOfUser(userID uint, pagination scopes.GormPager, scope ...string) (roleIDs []uint, totalCount int64, err error) {
+	query := repository.Database.Table("user_roles").Where("user_roles.user_id = ?", userID)
+	if len(scope) > 0 {
+		query = query.Where("user_roles.scope = ?", scope[0])
+	}
+
+	totalCount = -1
+	if pagination == nil || !pagination.SkipCount() {
+		query = query.Count(&totalCount)
+	}
+
+	if err = query.Scopes(repository.paginate(pagination)).Pluck("user_roles.role_id", &roleIDs).Error; err != nil {
+		return
+	}
+
+	if keyset, ok := pagination.(*scopes.KeysetPager); ok && len(roleIDs) > 0 {
+		keyset.Next = roleIDs[len(roleIDs)-1]
+	}
 	return
 }
 
 // GetRoleIDsOfPermission get role ids of permission. (with pagination)
+// When pagination is a *scopes.KeysetPager (use Column: "role_id"), the
+// COUNT(*) is skipped (totalCount is -1) and pagination.Next is set to the
+// cursor to resume from.
 // @param uint
 // @param repositories_scopes.GormPager
 // @return []uint, int64, error
 func (repository *RoleRepository) GetRoleIDsOfPermission(permissionID uint, pagination scopes.GormPager) (roleIDs []uint, totalCount int64, err error) {
-	err = repository.Database.Table("role_permissions").Where("role_permissions.permission_id = ?", permissionID).Count(&totalCount).Scopes(repository.paginate(pagination)).Pluck("role_permissions.role_id", &roleIDs).Error
+	query := repository.Database.Table("role_permissions").Where("role_permissions.permission_id = ?", permissionID)
+
+	totalCount = -1
+	if pagination == nil || !pagination.SkipCount() {
+		query = query.Count(&totalCount)
+	}
+
+	if err = query.Scopes(repository.paginate(pagination)).Pluck("role_permissions.role_id", &roleIDs).Error; err != nil {
+		return
+	}
+
+	if keyset, ok := pagination.(*scopes.KeysetPager); ok && len(roleIDs) > 0 {
+		keyset.Next = roleIDs[len(roleIDs)-1]
+	}
 	return
 }
 
@@ -170,7 +419,11 @@ func (repository *RoleRepository) GetRoleIDsOfPermission(permissionID uint, pagi
 // @param *models.Role
 // @return error
 func (repository *RoleRepository) FirstOrCreate(role *models.Role) error {
-	return repository.Database.Where(models.Role{GuardName: role.GuardName}).FirstOrCreate(role).Error
+	result := repository.Database.Where(models.Role{GuardName: role.GuardName}).FirstOrCreate(role)
+	if result.Error == nil && result.RowsAffected > 0 {
+		repository.publish(events.Event{Type: events.RoleCreated, RoleID: role.ID})
+	}
+	return result.Error
 }
 
 // Updates update role.
@@ -185,7 +438,11 @@ func (repository *RoleRepository) Updates(role *models.Role, updates map[string]
 // @param *models.Role
 // @return error
 func (repository *RoleRepository) Delete(role *models.Role) (err error) {
-	return repository.Database.Transaction(func(tx *gorm.DB) error {
+	if err = repository.Database.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Role{}).Where("roles.parent_role_id = ?", role.ID).Update("parent_role_id", nil).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
 		if err := tx.Where("user_roles.role_id = ?", role.ID).Delete(&pivot.UserRoles{}).Error; err != nil {
 			tx.Rollback()
 			return err
@@ -195,7 +452,207 @@ func (repository *RoleRepository) Delete(role *models.Role) (err error) {
 			return err
 		}
 		return nil
-	})
+	}); err == nil {
+		repository.publish(events.Event{Type: events.RoleDeleted, RoleID: role.ID})
+	}
+	return
+}
+
+// HIERARCHY
+
+// SetParent sets the role's parent, making it inherit the parent's permissions.
+// Pass a nil parent to detach the role and make it a hierarchy root.
+// Returns ErrCycleDetected if the parent is the role itself or one of its own descendants.
+// @param *models.Role
+// @param *models.Role
+// @return error
+func (repository *RoleRepository) SetParent(role *models.Role, parent *models.Role) (err error) {
+	var parentRoleID *uint
+	if parent != nil {
+		if parent.ID == role.ID {
+			return ErrCycleDetected
+		}
+
+		var descendants collections.Role
+		if descendants, err = repository.GetDescendants(*role); err != nil {
+			return
+		}
+		for _, descendant := range descendants {
+			if descendant.ID == parent.ID {
+				return ErrCycleDetected
+			}
+		}
+
+		parentRoleID = &parent.ID
+	}
+
+	if err = repository.Database.Model(role).Update("parent_role_id", parentRoleID).Error; err == nil {
+		role.ParentRoleID = parentRoleID
+	}
+	return
+}
+
+// GetAncestors returns the roles the given role inherits from, ordered from
+// its immediate parent up to the root of the hierarchy. The walk stops at the
+// first role it has already visited, so a cycle in the stored data can't make
+// it loop forever.
+// @param models.Role
+// @return collections.Role, error
+func (repository *RoleRepository) GetAncestors(role models.Role) (ancestors collections.Role, err error) {
+	visited := map[uint]bool{role.ID: true}
+	current := role
+	for current.ParentRoleID != nil && !visited[*current.ParentRoleID] {
+		visited[*current.ParentRoleID] = true
+		var parent models.Role
+		if err = repository.Database.First(&parent, "roles.id = ?", *current.ParentRoleID).Error; err != nil {
+			return
+		}
+		ancestors = append(ancestors, parent)
+		current = parent
+	}
+	return
+}
+
+// GetDescendants returns every role that transitively inherits from the given role.
+// @param models.Role
+// @return collections.Role, error
+func (repository *RoleRepository) GetDescendants(role models.Role) (descendants collections.Role, err error) {
+	frontier := []uint{role.ID}
+	for len(frontier) > 0 {
+		var children collections.Role
+		if err = repository.Database.Where("roles.parent_role_id IN (?)", frontier).Find(&children).Error; err != nil {
+			return
+		}
+		if len(children) == 0 {
+			break
+		}
+
+		descendants = append(descendants, children...)
+		frontier = children.IDs()
+	}
+	return
+}
+
+// expandWithAncestors returns the given roles together with every role they
+// transitively inherit from, deduplicated. It prefers a recursive CTE on
+// drivers that support one (see supportsRecursiveCTE) and falls back to a
+// bounded BFS through GetAncestors otherwise.
+// @param collections.Role
+// @return collections.Role, error
+func (repository *RoleRepository) expandWithAncestors(roles collections.Role) (expanded collections.Role, err error) {
+	expanded = append(expanded, roles...)
+
+	seen := make(map[uint]bool, len(roles))
+	for _, role := range roles {
+		seen[role.ID] = true
+	}
+
+	add := func(candidates collections.Role) {
+		for _, candidate := range candidates {
+			if !seen[candidate.ID] {
+				seen[candidate.ID] = true
+				expanded = append(expanded, candidate)
+			}
+		}
+	}
+
+	if repository.supportsRecursiveCTE() {
+		var ancestors collections.Role
+		if err = repository.Database.Raw(roleAncestorsCTE, roles.IDs()).Scan(&ancestors).Error; err != nil {
+			return
+		}
+		add(ancestors)
+	} else {
+		for _, role := range roles {
+			var ancestors collections.Role
+			if ancestors, err = repository.GetAncestors(role); err != nil {
+				return
+			}
+			add(ancestors)
+		}
+	}
+	return
+}
+
+// supportsRecursiveCTE reports whether the connected database can run the
+// WITH RECURSIVE role-ancestors query: Postgres always can, MySQL only at
+// 8.0+ (5.x has no recursive CTE support), and every other driver falls back
+// to the BFS-through-GetAncestors path. The result is cached per repository
+// since the connected server doesn't change mid-lifetime.
+// @return bool
+func (repository *RoleRepository) supportsRecursiveCTE() bool {
+	cache := repository.sharedCache()
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if cache.cteSupport != nil {
+		return *cache.cteSupport
+	}
+
+	var supported bool
+	switch repository.Database.Dialector.Name() {
+	case "postgres":
+		supported = true
+	case "mysql":
+		var version string
+		if err := repository.Database.Raw("SELECT VERSION()").Scan(&version).Error; err == nil {
+			supported = mysqlMajorVersion(version) >= 8
+		}
+	}
+
+	cache.cteSupport = &supported
+	return supported
+}
+
+// mysqlMajorVersion extracts the leading major version number from a MySQL
+// version string such as "8.0.31" or "5.7.41-log".
+// @param string
+// @return int
+func mysqlMajorVersion(version string) (major int) {
+	for _, r := range version {
+		if r < '0' || r > '9' {
+			break
+		}
+		major = major*10 + int(r-'0')
+	}
+	return
+}
+
+// SCOPED ASSIGNMENTS
+
+// AddRoleToUserInScope grants the user a role within a scope (e.g. a tenant,
+// organization or project id). Pass an empty scope for a global grant.
+// @param uint
+// @param uint
+// @param string
+// @return error
+func (repository *RoleRepository) AddRoleToUserInScope(userID uint, roleID uint, scope string) (err error) {
+	if err = repository.Database.Create(&pivot.UserRoles{UserID: userID, RoleID: roleID, Scope: scope}).Error; err == nil {
+		repository.publish(events.Event{Type: events.UserRoleAssigned, RoleID: roleID, UserID: userID, Scope: scope})
+	}
+	return
+}
+
+// RemoveRoleFromUserInScope revokes a role previously granted to the user within a scope.
+// @param uint
+// @param uint
+// @param string
+// @return error
+func (repository *RoleRepository) RemoveRoleFromUserInScope(userID uint, roleID uint, scope string) (err error) {
+	return repository.Database.Where("user_roles.user_id = ? AND user_roles.role_id = ? AND user_roles.scope = ?", userID, roleID, scope).Delete(&pivot.UserRoles{}).Error
+}
+
+// GetRolesOfUserInScope get the roles granted to the user within a scope.
+// @param uint
+// @param string
+// @return collections.Role, error
+func (repository *RoleRepository) GetRolesOfUserInScope(userID uint, scope string) (roles collections.Role, err error) {
+	var roleIDs []uint
+	if roleIDs, _, err = repository.GetRoleIDsOfUser(userID, nil, scope); err != nil {
+		return
+	}
+	err = repository.Database.Where("roles.id IN (?)", roleIDs).Find(&roles).Error
+	return
 }
 
 // ACTIONS
@@ -204,63 +661,342 @@ func (repository *RoleRepository) Delete(role *models.Role) (err error) {
 // @param *models.Role
 // @param collections.Permission
 // @return error
-func (repository *RoleRepository) AddPermissions(role *models.Role, permissions collections.Permission) error {
-	return repository.Database.Model(role).Association("Permissions").Append(permissions.Origin())
+func (repository *RoleRepository) AddPermissions(role *models.Role, permissions collections.Permission) (err error) {
+	before, err := repository.permissionIDsOf(role)
+	if err != nil {
+		return
+	}
+	if err = repository.Database.Model(role).Association("Permissions").Append(permissions.Origin()); err != nil {
+		return
+	}
+	after, err := repository.permissionIDsOf(role)
+	if err != nil {
+		return
+	}
+	repository.publish(events.Event{Type: events.PermissionsAttached, RoleID: role.ID, Before: before, After: after})
+	return
 }
 
 // ReplacePermissions replace permissions of role.
 // @param *models.Role
 // @param collections.Permission
 // @return error
-func (repository *RoleRepository) ReplacePermissions(role *models.Role, permissions collections.Permission) error {
-	return repository.Database.Model(role).Association("Permissions").Replace(permissions.Origin())
+func (repository *RoleRepository) ReplacePermissions(role *models.Role, permissions collections.Permission) (err error) {
+	before, err := repository.permissionIDsOf(role)
+	if err != nil {
+		return
+	}
+	if err = repository.Database.Model(role).Association("Permissions").Replace(permissions.Origin()); err != nil {
+		return
+	}
+
+	after := permissions.IDs()
+	beforeSet := make(map[uint]bool, len(before))
+	for _, id := range before {
+		beforeSet[id] = true
+	}
+	afterSet := make(map[uint]bool, len(after))
+	for _, id := range after {
+		afterSet[id] = true
+	}
+
+	var attached, detached bool
+	for _, id := range after {
+		if !beforeSet[id] {
+			attached = true
+			break
+		}
+	}
+	for _, id := range before {
+		if !afterSet[id] {
+			detached = true
+			break
+		}
+	}
+
+	if attached {
+		repository.publish(events.Event{Type: events.PermissionsAttached, RoleID: role.ID, Before: before, After: after})
+	}
+	if detached {
+		repository.publish(events.Event{Type: events.PermissionsDetached, RoleID: role.ID, Before: before, After: after})
+	}
+	return
 }
 
 // RemovePermissions remove permissions of role.
 // @param *models.Role
 // @param collections.Permission
 // @return error
-func (repository *RoleRepository) RemovePermissions(role *models.Role, permissions collections.Permission) error {
-	return repository.Database.Model(role).Association("Permissions").Delete(permissions.Origin())
+func (repository *RoleRepository) RemovePermissions(role *models.Role, permissions collections.Permission) (err error) {
+	before, err := repository.permissionIDsOf(role)
+	if err != nil {
+		return
+	}
+	if err = repository.Database.Model(role).Association("Permissions").Delete(permissions.Origin()); err != nil {
+		return
+	}
+	after, err := repository.permissionIDsOf(role)
+	if err != nil {
+		return
+	}
+	repository.publish(events.Event{Type: events.PermissionsDetached, RoleID: role.ID, Before: before, After: after})
+	return
 }
 
 // ClearPermissions remove all permissions of role.
 // @param *models.Role
 // @return error
 func (repository *RoleRepository) ClearPermissions(role *models.Role) (err error) {
-	return repository.Database.Model(role).Association("Permissions").Clear()
+	before, err := repository.permissionIDsOf(role)
+	if err != nil {
+		return
+	}
+	if err = repository.Database.Model(role).Association("Permissions").Clear(); err != nil {
+		return
+	}
+	repository.publish(events.Event{Type: events.PermissionsDetached, RoleID: role.ID, Before: before, After: nil})
+	return
+}
+
+// SyncPermissions brings a role's permissions to exactly the desired set in a
+// single transaction, locking the role's current grants for the duration so
+// concurrent syncs can't race, and reports what was added and removed.
+// @param *models.Role
+// @param collections.Permission
+// @return collections.Permission, collections.Permission, error
+func (repository *RoleRepository) SyncPermissions(role *models.Role, desired collections.Permission) (added collections.Permission, removed collections.Permission, err error) {
+	desiredIDs := make(map[uint]bool, len(desired))
+	for _, permission := range desired {
+		desiredIDs[permission.ID] = true
+	}
+
+	var beforeIDs []uint
+	err = repository.Database.Transaction(func(tx *gorm.DB) error {
+		var current collections.Permission
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Model(role).Association("Permissions").Find(&current); err != nil {
+			return err
+		}
+		beforeIDs = current.IDs()
+
+		currentIDs := make(map[uint]bool, len(current))
+		for _, permission := range current {
+			currentIDs[permission.ID] = true
+		}
+
+		for _, permission := range desired {
+			if !currentIDs[permission.ID] {
+				added = append(added, permission)
+			}
+		}
+		for _, permission := range current {
+			if !desiredIDs[permission.ID] {
+				removed = append(removed, permission)
+			}
+		}
+
+		if len(added) == 0 && len(removed) == 0 {
+			return nil
+		}
+		return tx.Model(role).Association("Permissions").Replace(desired.Origin())
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(added) > 0 {
+		repository.publish(events.Event{Type: events.PermissionsAttached, RoleID: role.ID, Before: beforeIDs, After: desired.IDs()})
+	}
+	if len(removed) > 0 {
+		repository.publish(events.Event{Type: events.PermissionsDetached, RoleID: role.ID, Before: beforeIDs, After: desired.IDs()})
+	}
+	return
+}
+
+// SyncRolesOfUser brings the roles granted to a user within a scope to
+// exactly desiredRoleIDs in a single transaction, locking the user's current
+// grants for the duration, and reports which role ids were added and removed.
+// @param uint
+// @param []uint
+// @param ...string
+// @return []uint, []uint, error
+func (repository *RoleRepository) SyncRolesOfUser(userID uint, desiredRoleIDs []uint, scope ...string) (added []uint, removed []uint, err error) {
+	appliedScope := ""
+	if len(scope) > 0 {
+		appliedScope = scope[0]
+	}
+
+	desiredSet := make(map[uint]bool, len(desiredRoleIDs))
+	for _, roleID := range desiredRoleIDs {
+		desiredSet[roleID] = true
+	}
+
+	err = repository.Database.Transaction(func(tx *gorm.DB) error {
+		var current []pivot.UserRoles
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_roles.user_id = ? AND user_roles.scope = ?", userID, appliedScope).Find(&current).Error; err != nil {
+			return err
+		}
+
+		currentSet := make(map[uint]bool, len(current))
+		for _, userRole := range current {
+			currentSet[userRole.RoleID] = true
+		}
+
+		for _, roleID := range desiredRoleIDs {
+			if !currentSet[roleID] {
+				added = append(added, roleID)
+			}
+		}
+		for _, userRole := range current {
+			if !desiredSet[userRole.RoleID] {
+				removed = append(removed, userRole.RoleID)
+			}
+		}
+
+		for _, roleID := range added {
+			if err := tx.Create(&pivot.UserRoles{UserID: userID, RoleID: roleID, Scope: appliedScope}).Error; err != nil {
+				return err
+			}
+		}
+		for _, roleID := range removed {
+			if err := tx.Where("user_roles.user_id = ? AND user_roles.role_id = ? AND user_roles.scope = ?", userID, roleID, appliedScope).Delete(&pivot.UserRoles{}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, roleID := range added {
+		repository.publish(events.Event{Type: events.UserRoleAssigned, RoleID: roleID, UserID: userID, Scope: appliedScope})
+	}
+	return
 }
 
 // Controls
 
-// HasPermission does the role or any of the roles have given permission?
+// HasPermission does the role or any of the roles, or anything they inherit
+// from, have the given permission - either directly, or through a wildcard
+// grant whose pattern resolves to it?
 // @param collections.Role
 // @param models.Permission
 // @return bool, error
 func (repository *RoleRepository) HasPermission(roles collections.Role, permission models.Permission) (b bool, err error) {
-	var count int64
-	err = repository.Database.Table("role_permissions").Where("role_permissions.role_id IN (?)", roles.IDs()).Where("role_permissions.permission_id = ?", permission.ID).Count(&count).Error
-	return count > 0, err
+	var withAncestors collections.Role
+	if withAncestors, err = repository.expandWithAncestors(roles); err != nil {
+		return
+	}
+	return repository.roleSetHasPermission(withAncestors, permission)
 }
 
-// HasAllPermissions does the role or roles have all the given permissions?
+// HasAllPermissions does the role or roles, or anything they inherit from, have all the given permissions?
 // @param collections.Role
 // @param collections.Permission
 // @return bool, error
 func (repository *RoleRepository) HasAllPermissions(roles collections.Role, permissions collections.Permission) (b bool, err error) {
-	var count int64
-	err = repository.Database.Table("role_permissions").Where("role_permissions.role_id IN (?)", roles.IDs()).Where("role_permissions.permission_id IN (?)", permissions.IDs()).Count(&count).Error
-	return roles.Len()*permissions.Len() == count, err
+	var withAncestors collections.Role
+	if withAncestors, err = repository.expandWithAncestors(roles); err != nil {
+		return
+	}
+
+	for _, permission := range permissions {
+		var has bool
+		if has, err = repository.roleSetHasPermission(withAncestors, permission); err != nil || !has {
+			return false, err
+		}
+	}
+	return true, nil
 }
 
-// HasAnyPermissions does the role or roles have any of the given permissions?
+// HasAnyPermissions does the role or roles, or anything they inherit from, have any of the given permissions?
 // @param collections.Role
 // @param collections.Permission
 // @return bool, error
 func (repository *RoleRepository) HasAnyPermissions(roles collections.Role, permissions collections.Permission) (b bool, err error) {
+	var withAncestors collections.Role
+	if withAncestors, err = repository.expandWithAncestors(roles); err != nil {
+		return
+	}
+
+	for _, permission := range permissions {
+		var has bool
+		if has, err = repository.roleSetHasPermission(withAncestors, permission); err != nil || has {
+			return has, err
+		}
+	}
+	return false, nil
+}
+
+// roleSetHasPermission checks whether the given (already hierarchy-expanded)
+// roles grant permission, either directly or via a matching wildcard pattern.
+// @param collections.Role
+// @param models.Permission
+// @return bool, error
+func (repository *RoleRepository) roleSetHasPermission(roles collections.Role, permission models.Permission) (b bool, err error) {
 	var count int64
-	err = repository.Database.Table("role_permissions").Where("role_permissions.role_id IN (?)", roles.IDs()).Where("role_permissions.permission_id IN (?)", permissions.IDs()).Count(&count).Error
-	return count > 0, err
+	if err = repository.Database.Table("role_permissions").Where("role_permissions.role_id IN (?)", roles.IDs()).Where("role_permissions.permission_id = ?", permission.ID).Count(&count).Error; err != nil {
+		return
+	}
+	if count > 0 {
+		return true, nil
+	}
+
+	var patterns []string
+	if err = repository.Database.Table("role_permissions").
+		Joins("INNER JOIN permissions ON permissions.id = role_permissions.permission_id").
+		Where("role_permissions.role_id IN (?)", roles.IDs()).
+		Where("permissions.is_pattern = ?", true).
+		Pluck("permissions.guard_name", &patterns).Error; err != nil {
+		return
+	}
+
+	cache := repository.patternCache()
+	for _, pattern := range patterns {
+		if cache.Matches(pattern, permission.GuardName) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// HasPermissionInScope does the user have the given permission through a role granted in that scope?
+// @param uint
+// @param string
+// @param models.Permission
+// @return bool, error
+func (repository *RoleRepository) HasPermissionInScope(userID uint, scope string, permission models.Permission) (b bool, err error) {
+	roles, err := repository.GetRolesOfUserInScope(userID, scope)
+	if err != nil {
+		return
+	}
+	return repository.HasPermission(roles, permission)
+}
+
+// HasAllPermissionsInScope does the user have all the given permissions through roles granted in that scope?
+// @param uint
+// @param string
+// @param collections.Permission
+// @return bool, error
+func (repository *RoleRepository) HasAllPermissionsInScope(userID uint, scope string, permissions collections.Permission) (b bool, err error) {
+	roles, err := repository.GetRolesOfUserInScope(userID, scope)
+	if err != nil {
+		return
+	}
+	return repository.HasAllPermissions(roles, permissions)
+}
+
+// HasAnyPermissionsInScope does the user have any of the given permissions through roles granted in that scope?
+// @param uint
+// @param string
+// @param collections.Permission
+// @return bool, error
+func (repository *RoleRepository) HasAnyPermissionsInScope(userID uint, scope string, permissions collections.Permission) (b bool, err error) {
+	roles, err := repository.GetRolesOfUserInScope(userID, scope)
+	if err != nil {
+		return
+	}
+	return repository.HasAnyPermissions(roles, permissions)
 }
 
 // paginate pagging if pagination option is true.
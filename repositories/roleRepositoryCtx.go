@@ -0,0 +1,193 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/imKota/permify_gorm/collections"
+	"github.com/imKota/permify_gorm/models"
+	"github.com/imKota/permify_gorm/repositories/scopes"
+)
+
+// withContext returns a shallow copy of the repository whose Database is
+// bound to ctx, so the plain (non-Ctx) methods can be reused as-is. The
+// cache is initialized on the receiver before cloning so the clone shares
+// the same cache pointer as the original, rather than each clone lazily
+// building its own that's discarded once the *Ctx call returns.
+// @param context.Context
+// @return *RoleRepository
+func (repository *RoleRepository) withContext(ctx context.Context) *RoleRepository {
+	repository.sharedCache()
+	clone := *repository
+	clone.Database = repository.Database.WithContext(ctx)
+	return &clone
+}
+
+// MigrateCtx is the context-aware variant of Migrate.
+func (repository *RoleRepository) MigrateCtx(ctx context.Context) (err error) {
+	return repository.withContext(ctx).Migrate()
+}
+
+// GetRoleByIDCtx is the context-aware variant of GetRoleByID.
+func (repository *RoleRepository) GetRoleByIDCtx(ctx context.Context, ID uint) (role models.Role, err error) {
+	return repository.withContext(ctx).GetRoleByID(ID)
+}
+
+// GetRoleByIDWithPermissionsCtx is the context-aware variant of GetRoleByIDWithPermissions.
+func (repository *RoleRepository) GetRoleByIDWithPermissionsCtx(ctx context.Context, ID uint) (role models.Role, err error) {
+	return repository.withContext(ctx).GetRoleByIDWithPermissions(ID)
+}
+
+// GetRoleByIDWithInheritedPermissionsCtx is the context-aware variant of GetRoleByIDWithInheritedPermissions.
+func (repository *RoleRepository) GetRoleByIDWithInheritedPermissionsCtx(ctx context.Context, ID uint) (role models.Role, err error) {
+	return repository.withContext(ctx).GetRoleByIDWithInheritedPermissions(ID)
+}
+
+// GetRoleByGuardNameCtx is the context-aware variant of GetRoleByGuardName.
+func (repository *RoleRepository) GetRoleByGuardNameCtx(ctx context.Context, guardName string) (role models.Role, err error) {
+	return repository.withContext(ctx).GetRoleByGuardName(guardName)
+}
+
+// GetRoleByGuardNameWithPermissionsCtx is the context-aware variant of GetRoleByGuardNameWithPermissions.
+func (repository *RoleRepository) GetRoleByGuardNameWithPermissionsCtx(ctx context.Context, guardName string) (role models.Role, err error) {
+	return repository.withContext(ctx).GetRoleByGuardNameWithPermissions(guardName)
+}
+
+// GetRolesCtx is the context-aware variant of GetRoles.
+func (repository *RoleRepository) GetRolesCtx(ctx context.Context, roleIDs []uint) (roles collections.Role, err error) {
+	return repository.withContext(ctx).GetRoles(roleIDs)
+}
+
+// GetRolesWithPermissionsCtx is the context-aware variant of GetRolesWithPermissions.
+func (repository *RoleRepository) GetRolesWithPermissionsCtx(ctx context.Context, roleIDs []uint) (roles collections.Role, err error) {
+	return repository.withContext(ctx).GetRolesWithPermissions(roleIDs)
+}
+
+// GetRolesByGuardNamesCtx is the context-aware variant of GetRolesByGuardNames.
+func (repository *RoleRepository) GetRolesByGuardNamesCtx(ctx context.Context, guardNames []string) (roles collections.Role, err error) {
+	return repository.withContext(ctx).GetRolesByGuardNames(guardNames)
+}
+
+// GetRolesByGuardNamesWithPermissionsCtx is the context-aware variant of GetRolesByGuardNamesWithPermissions.
+func (repository *RoleRepository) GetRolesByGuardNamesWithPermissionsCtx(ctx context.Context, guardNames []string) (roles collections.Role, err error) {
+	return repository.withContext(ctx).GetRolesByGuardNamesWithPermissions(guardNames)
+}
+
+// GetRoleIDsCtx is the context-aware variant of GetRoleIDs.
+func (repository *RoleRepository) GetRoleIDsCtx(ctx context.Context, pagination scopes.GormPager) (roleIDs []uint, totalCount int64, err error) {
+	return repository.withContext(ctx).GetRoleIDs(pagination)
+}
+
+// GetRoleIDsOfUserCtx is the context-aware variant of GetRoleIDsOfUser.
+func (repository *RoleRepository) GetRoleIDsOfUserCtx(ctx context.Context, userID uint, pagination scopes.GormPager, scope ...string) (roleIDs []uint, totalCount int64, err error) {
+	return repository.withContext(ctx).GetRoleIDsOfUser(userID, pagination, scope...)
+}
+
+// GetRoleIDsOfPermissionCtx is the context-aware variant of GetRoleIDsOfPermission.
+func (repository *RoleRepository) GetRoleIDsOfPermissionCtx(ctx context.Context, permissionID uint, pagination scopes.GormPager) (roleIDs []uint, totalCount int64, err error) {
+	return repository.withContext(ctx).GetRoleIDsOfPermission(permissionID, pagination)
+}
+
+// FirstOrCreateCtx is the context-aware variant of FirstOrCreate.
+func (repository *RoleRepository) FirstOrCreateCtx(ctx context.Context, role *models.Role) (err error) {
+	return repository.withContext(ctx).FirstOrCreate(role)
+}
+
+// UpdatesCtx is the context-aware variant of Updates.
+func (repository *RoleRepository) UpdatesCtx(ctx context.Context, role *models.Role, updates map[string]interface{}) (err error) {
+	return repository.withContext(ctx).Updates(role, updates)
+}
+
+// DeleteCtx is the context-aware variant of Delete.
+func (repository *RoleRepository) DeleteCtx(ctx context.Context, role *models.Role) (err error) {
+	return repository.withContext(ctx).Delete(role)
+}
+
+// SetParentCtx is the context-aware variant of SetParent.
+func (repository *RoleRepository) SetParentCtx(ctx context.Context, role *models.Role, parent *models.Role) (err error) {
+	return repository.withContext(ctx).SetParent(role, parent)
+}
+
+// GetAncestorsCtx is the context-aware variant of GetAncestors.
+func (repository *RoleRepository) GetAncestorsCtx(ctx context.Context, role models.Role) (ancestors collections.Role, err error) {
+	return repository.withContext(ctx).GetAncestors(role)
+}
+
+// GetDescendantsCtx is the context-aware variant of GetDescendants.
+func (repository *RoleRepository) GetDescendantsCtx(ctx context.Context, role models.Role) (descendants collections.Role, err error) {
+	return repository.withContext(ctx).GetDescendants(role)
+}
+
+// AddRoleToUserInScopeCtx is the context-aware variant of AddRoleToUserInScope.
+func (repository *RoleRepository) AddRoleToUserInScopeCtx(ctx context.Context, userID uint, roleID uint, scope string) (err error) {
+	return repository.withContext(ctx).AddRoleToUserInScope(userID, roleID, scope)
+}
+
+// RemoveRoleFromUserInScopeCtx is the context-aware variant of RemoveRoleFromUserInScope.
+func (repository *RoleRepository) RemoveRoleFromUserInScopeCtx(ctx context.Context, userID uint, roleID uint, scope string) (err error) {
+	return repository.withContext(ctx).RemoveRoleFromUserInScope(userID, roleID, scope)
+}
+
+// GetRolesOfUserInScopeCtx is the context-aware variant of GetRolesOfUserInScope.
+func (repository *RoleRepository) GetRolesOfUserInScopeCtx(ctx context.Context, userID uint, scope string) (roles collections.Role, err error) {
+	return repository.withContext(ctx).GetRolesOfUserInScope(userID, scope)
+}
+
+// AddPermissionsCtx is the context-aware variant of AddPermissions.
+func (repository *RoleRepository) AddPermissionsCtx(ctx context.Context, role *models.Role, permissions collections.Permission) (err error) {
+	return repository.withContext(ctx).AddPermissions(role, permissions)
+}
+
+// ReplacePermissionsCtx is the context-aware variant of ReplacePermissions.
+func (repository *RoleRepository) ReplacePermissionsCtx(ctx context.Context, role *models.Role, permissions collections.Permission) (err error) {
+	return repository.withContext(ctx).ReplacePermissions(role, permissions)
+}
+
+// RemovePermissionsCtx is the context-aware variant of RemovePermissions.
+func (repository *RoleRepository) RemovePermissionsCtx(ctx context.Context, role *models.Role, permissions collections.Permission) (err error) {
+	return repository.withContext(ctx).RemovePermissions(role, permissions)
+}
+
+// ClearPermissionsCtx is the context-aware variant of ClearPermissions.
+func (repository *RoleRepository) ClearPermissionsCtx(ctx context.Context, role *models.Role) (err error) {
+	return repository.withContext(ctx).ClearPermissions(role)
+}
+
+// SyncPermissionsCtx is the context-aware variant of SyncPermissions.
+func (repository *RoleRepository) SyncPermissionsCtx(ctx context.Context, role *models.Role, desired collections.Permission) (added collections.Permission, removed collections.Permission, err error) {
+	return repository.withContext(ctx).SyncPermissions(role, desired)
+}
+
+// SyncRolesOfUserCtx is the context-aware variant of SyncRolesOfUser.
+func (repository *RoleRepository) SyncRolesOfUserCtx(ctx context.Context, userID uint, desiredRoleIDs []uint, scope ...string) (added []uint, removed []uint, err error) {
+	return repository.withContext(ctx).SyncRolesOfUser(userID, desiredRoleIDs, scope...)
+}
+
+// HasPermissionCtx is the context-aware variant of HasPermission.
+func (repository *RoleRepository) HasPermissionCtx(ctx context.Context, roles collections.Role, permission models.Permission) (b bool, err error) {
+	return repository.withContext(ctx).HasPermission(roles, permission)
+}
+
+// HasAllPermissionsCtx is the context-aware variant of HasAllPermissions.
+func (repository *RoleRepository) HasAllPermissionsCtx(ctx context.Context, roles collections.Role, permissions collections.Permission) (b bool, err error) {
+	return repository.withContext(ctx).HasAllPermissions(roles, permissions)
+}
+
+// HasAnyPermissionsCtx is the context-aware variant of HasAnyPermissions.
+func (repository *RoleRepository) HasAnyPermissionsCtx(ctx context.Context, roles collections.Role, permissions collections.Permission) (b bool, err error) {
+	return repository.withContext(ctx).HasAnyPermissions(roles, permissions)
+}
+
+// HasPermissionInScopeCtx is the context-aware variant of HasPermissionInScope.
+func (repository *RoleRepository) HasPermissionInScopeCtx(ctx context.Context, userID uint, scope string, permission models.Permission) (b bool, err error) {
+	return repository.withContext(ctx).HasPermissionInScope(userID, scope, permission)
+}
+
+// HasAllPermissionsInScopeCtx is the context-aware variant of HasAllPermissionsInScope.
+func (repository *RoleRepository) HasAllPermissionsInScopeCtx(ctx context.Context, userID uint, scope string, permissions collections.Permission) (b bool, err error) {
+	return repository.withContext(ctx).HasAllPermissionsInScope(userID, scope, permissions)
+}
+
+// HasAnyPermissionsInScopeCtx is the context-aware variant of HasAnyPermissionsInScope.
+func (repository *RoleRepository) HasAnyPermissionsInScopeCtx(ctx context.Context, userID uint, scope string, permissions collections.Permission) (b bool, err error) {
+	return repository.withContext(ctx).HasAnyPermissionsInScope(userID, scope, permissions)
+}
@@ -0,0 +1,53 @@
+package scopes
+
+import (
+	"gorm.io/gorm"
+)
+
+// KeysetPager adds cursor (keyset) pagination to your gorm queries, avoiding
+// the performance cliff large OFFSETs hit on big role/permission tables.
+// Unlike GormPagination it doesn't require a COUNT(*) query.
+type KeysetPager struct {
+	// Column is the ordered, indexed column the cursor walks. Defaults to
+	// "id"; pass "role_id" when paging a pivot table such as user_roles or
+	// role_permissions, which has no surrogate id column of its own.
+	Column string
+
+	// After is the cursor to resume from: rows whose Column value is greater
+	// than After are returned. Zero starts from the beginning.
+	After uint
+
+	// Limit caps the number of rows returned. Zero means no limit.
+	Limit int
+
+	// Next is populated by the repository method after the query runs, with
+	// the cursor to pass as After on the following call.
+	Next uint
+}
+
+// ToPaginate implements GormPager.
+func (pager *KeysetPager) ToPaginate() func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		column := pager.column()
+		if pager.After > 0 {
+			db = db.Where(column+" > ?", pager.After)
+		}
+		db = db.Order(column)
+		if pager.Limit > 0 {
+			db = db.Limit(pager.Limit)
+		}
+		return db
+	}
+}
+
+// SkipCount implements GormPager. Keyset pagination doesn't need a total count.
+func (pager *KeysetPager) SkipCount() bool {
+	return true
+}
+
+func (pager *KeysetPager) column() string {
+	if pager.Column == "" {
+		return "id"
+	}
+	return pager.Column
+}
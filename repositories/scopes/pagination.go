@@ -10,6 +10,11 @@ import (
 // GormPager adds pagination capability to your gorm queries.
 type GormPager interface {
 	ToPaginate() func(db *gorm.DB) *gorm.DB
+
+	// SkipCount reports whether the caller should skip the COUNT(*) query
+	// that normally accompanies pagination. Offset pagination needs the
+	// count to compute page numbers; keyset pagination doesn't.
+	SkipCount() bool
 }
 
 // GormPagination represent pagination data for pagination.
@@ -23,3 +28,8 @@ func (r *GormPagination) ToPaginate() func(db *gorm.DB) *gorm.DB {
 		return db.Offset(helpers.OffsetCal(r.Pagination.GetPage(), r.Pagination.GetLimit())).Limit(r.Pagination.GetLimit())
 	}
 }
+
+// SkipCount implements GormPager. Offset pagination always needs the total count.
+func (r *GormPagination) SkipCount() bool {
+	return false
+}
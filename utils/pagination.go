@@ -0,0 +1,23 @@
+package utils
+
+// Pagination keeps pagination request data.
+type Pagination struct {
+	Page  int
+	Limit int
+}
+
+// GetPage get page, defaulting to the first page.
+func (p *Pagination) GetPage() int {
+	if p.Page <= 0 {
+		return 1
+	}
+	return p.Page
+}
+
+// GetLimit get limit, defaulting to 10.
+func (p *Pagination) GetLimit() int {
+	if p.Limit <= 0 {
+		return 10
+	}
+	return p.Limit
+}